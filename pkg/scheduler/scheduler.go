@@ -0,0 +1,204 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package scheduler implements a hashed timing wheel (in the style described by
+// Varghese & Lauck) for scheduling large numbers of delayed, cancelable callbacks
+// off a single ticker goroutine. It exists so that components which previously
+// spawned one goroutine+timer per tracked item (one per upstream host, one per
+// connection, ...) can instead share one Scheduler: O(1) insert and cancel, and
+// goroutine count stays flat as the number of scheduled items grows.
+package scheduler
+
+import (
+	"container/list"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWheelSize = 512
+	defaultTick      = 100 * time.Millisecond
+)
+
+// Handle references a single scheduled callback.
+type Handle interface {
+	// Cancel prevents the callback from firing, if it has not fired already.
+	Cancel()
+}
+
+type task struct {
+	fn     func()
+	rounds int
+
+	bucket   *bucket
+	element  *list.Element
+	canceled bool
+}
+
+// Cancel marks the task as canceled; the wheel drops it the next time it visits
+// the task's bucket, without needing to scan every bucket.
+func (t *task) Cancel() {
+	t.bucket.mutex.Lock()
+	defer t.bucket.mutex.Unlock()
+	t.canceled = true
+}
+
+// bucket holds every task due to fire during a particular pass over the wheel.
+// Tasks with a longer delay than one full revolution stay in their bucket across
+// multiple passes, decrementing rounds until it reaches zero (the classic
+// hashed-wheel "overflow" handling, without a separate overflow list).
+type bucket struct {
+	mutex sync.Mutex
+	tasks *list.List
+}
+
+func newBucket() *bucket {
+	return &bucket{tasks: list.New()}
+}
+
+// Scheduler is a single-level hashed timing wheel: one ticker goroutine advances
+// a cursor over a fixed number of buckets, firing whatever has accumulated in the
+// bucket it lands on. Schedule and Handle.Cancel are both O(1). There is no
+// hierarchy of wheels and no overflow list: a delay longer than one revolution
+// stays in its bucket with a round count.
+type Scheduler struct {
+	tick      time.Duration
+	wheel     []*bucket
+	wheelSize int
+
+	cursorMutex sync.Mutex
+	cursor      int
+
+	randMutex sync.Mutex
+	rander    *rand.Rand
+
+	stopOnce sync.Once
+	stopChan chan struct{}
+}
+
+// New returns a Scheduler with sane defaults (100ms tick, 512 buckets), started
+// and ready to accept Schedule calls.
+func New() *Scheduler {
+	return NewWithConfig(defaultTick, defaultWheelSize)
+}
+
+// NewWithConfig returns a started Scheduler with the given tick resolution and
+// wheel size. Smaller ticks give finer scheduling granularity at the cost of
+// more wakeups; a bigger wheel reduces how many tasks share a bucket.
+func NewWithConfig(tick time.Duration, wheelSize int) *Scheduler {
+	s := &Scheduler{
+		tick:      tick,
+		wheelSize: wheelSize,
+		wheel:     make([]*bucket, wheelSize),
+		rander:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		stopChan:  make(chan struct{}),
+	}
+	for i := range s.wheel {
+		s.wheel[i] = newBucket()
+	}
+	go s.run()
+	return s
+}
+
+func (s *Scheduler) run() {
+	ticker := time.NewTicker(s.tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.advance()
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+func (s *Scheduler) advance() {
+	s.cursorMutex.Lock()
+	cur := s.cursor
+	s.cursor = (s.cursor + 1) % s.wheelSize
+	s.cursorMutex.Unlock()
+
+	b := s.wheel[cur]
+	var ready []*task
+
+	b.mutex.Lock()
+	for e := b.tasks.Front(); e != nil; {
+		next := e.Next()
+		t := e.Value.(*task)
+
+		if t.canceled {
+			b.tasks.Remove(e)
+			e = next
+			continue
+		}
+		if t.rounds > 0 {
+			t.rounds--
+			e = next
+			continue
+		}
+
+		b.tasks.Remove(e)
+		ready = append(ready, t)
+		e = next
+	}
+	b.mutex.Unlock()
+
+	for _, t := range ready {
+		go t.fn()
+	}
+}
+
+// Schedule runs fn after `after` elapses, plus a uniform random jitter in
+// [0, jitter) when jitter > 0. Returns a Handle that cancels the callback if it
+// has not fired yet.
+func (s *Scheduler) Schedule(after, jitter time.Duration, fn func()) Handle {
+	if jitter > 0 {
+		s.randMutex.Lock()
+		after += time.Duration(s.rander.Int63n(int64(jitter)))
+		s.randMutex.Unlock()
+	}
+
+	ticks := int64(after / s.tick)
+	if ticks < 1 {
+		ticks = 1
+	}
+	rounds := int(ticks / int64(s.wheelSize))
+	offset := int(ticks % int64(s.wheelSize))
+
+	s.cursorMutex.Lock()
+	slot := (s.cursor + offset) % s.wheelSize
+	s.cursorMutex.Unlock()
+
+	b := s.wheel[slot]
+	t := &task{fn: fn, rounds: rounds, bucket: b}
+
+	b.mutex.Lock()
+	t.element = b.tasks.PushBack(t)
+	b.mutex.Unlock()
+
+	return t
+}
+
+// Stop halts the scheduler's ticker goroutine. Tasks that have not fired yet are
+// discarded. Stop is idempotent.
+func (s *Scheduler) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopChan)
+	})
+}
@@ -0,0 +1,64 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package scheduler
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+// BenchmarkScheduleGoroutineCount demonstrates the motivation for this package:
+// scheduling 10k long-delay tasks costs one ticker goroutine total, not one per
+// task, the way N health-check hosts used to each run their own goroutine+timer.
+func BenchmarkScheduleGoroutineCount(b *testing.B) {
+	const tasks = 10000
+
+	s := NewWithConfig(10*time.Millisecond, 64)
+	defer s.Stop()
+
+	// let the scheduler's own ticker goroutine settle before measuring
+	runtime.Gosched()
+	before := runtime.NumGoroutine()
+
+	handles := make([]Handle, 0, tasks)
+	for i := 0; i < tasks; i++ {
+		handles = append(handles, s.Schedule(time.Hour, 0, func() {}))
+	}
+
+	after := runtime.NumGoroutine()
+	if grew := after - before; grew > 10 {
+		b.Fatalf("goroutine count grew by %d after scheduling %d tasks, want O(1)", grew, tasks)
+	}
+
+	for _, h := range handles {
+		h.Cancel()
+	}
+}
+
+// BenchmarkScheduleAndCancel measures the O(1) insert/cancel cost itself.
+func BenchmarkScheduleAndCancel(b *testing.B) {
+	s := NewWithConfig(10*time.Millisecond, 64)
+	defer s.Stop()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h := s.Schedule(time.Hour, 0, func() {})
+		h.Cancel()
+	}
+}
@@ -0,0 +1,118 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package prometheus exposes pkg/stats metrics in Prometheus exposition format.
+// Unlike the stats.Exporter hook (which is push-based, for sinks like statsd),
+// Prometheus is pull-based: Collect walks stats.GetAll() fresh on every scrape,
+// so there is nothing to register up front and no stale state to expire.
+package prometheus
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rcrowley/go-metrics"
+
+	"github.com/alipay/sofa-mosn/pkg/stats"
+)
+
+var invalidNameChar = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+var percentiles = []struct {
+	suffix string
+	p      float64
+}{
+	{"p50", 0.5},
+	{"p90", 0.9},
+	{"p99", 0.99},
+}
+
+// collector adapts the pkg/stats store to prometheus.Collector. It is unchecked:
+// Describe emits nothing, since the set of (type, labels) pairs backing
+// stats.GetAll() grows as hosts/clusters come and go.
+type collector struct{}
+
+// NewCollector returns a prometheus.Collector backed by the default pkg/stats store.
+func NewCollector() prometheus.Collector {
+	return &collector{}
+}
+
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	for _, m := range stats.GetAll() {
+		keys, vals := m.SortedLabels()
+		if len(keys) > stats.MaxLabelCount() {
+			continue
+		}
+		labelNames := make([]string, len(keys))
+		for i, k := range keys {
+			labelNames[i] = sanitize(k)
+		}
+
+		name := sanitize(m.Type())
+		m.Each(func(key string, i interface{}) {
+			fqName := name + "_" + sanitize(key)
+			switch metric := i.(type) {
+			case metrics.Counter:
+				emit(ch, fqName, labelNames, vals, prometheus.CounterValue, float64(metric.Count()))
+			case metrics.Gauge:
+				emit(ch, fqName, labelNames, vals, prometheus.GaugeValue, float64(metric.Value()))
+			case metrics.Histogram:
+				ps := metric.Percentiles([]float64{0.5, 0.9, 0.99})
+				for i, pct := range percentiles {
+					emit(ch, fqName+"_"+pct.suffix, labelNames, vals, prometheus.GaugeValue, ps[i])
+				}
+			}
+		})
+	}
+}
+
+func emit(ch chan<- prometheus.Metric, fqName string, labelNames, labelVals []string, valueType prometheus.ValueType, value float64) {
+	desc := prometheus.NewDesc(fqName, fqName, labelNames, nil)
+	m, err := prometheus.NewConstMetric(desc, valueType, value, labelVals...)
+	if err != nil {
+		// a malformed name/label slipped past sanitize; drop the sample rather
+		// than fail the whole scrape
+		return
+	}
+	ch <- m
+}
+
+// sanitize maps a stats type/label/key to a valid Prometheus identifier:
+// [a-zA-Z_][a-zA-Z0-9_]*
+func sanitize(s string) string {
+	s = invalidNameChar.ReplaceAllString(s, "_")
+	if s == "" {
+		return "_"
+	}
+	if s[0] >= '0' && s[0] <= '9' {
+		s = "_" + s
+	}
+	return s
+}
+
+// Handler returns an http.Handler serving the default pkg/stats store in
+// Prometheus exposition format, suitable for mounting on the admin server, e.g.
+// mux.Handle("/metrics", prometheus.Handler()).
+func Handler() http.Handler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(NewCollector())
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,92 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prometheus
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/alipay/sofa-mosn/pkg/stats"
+)
+
+// BenchmarkCollectDoesNotBlockCallbacks registers ~10k metrics, the rough order
+// of magnitude a large cluster's health checkers would produce, and scrapes them
+// concurrently with a loop that keeps calling stats.NewStats, the same call a
+// host joining/leaving a cluster makes. stats.NewStats and Collect (via
+// stats.GetAll) contend on the same defaultStore.mutex, so this actually
+// exercises the claim: a Prometheus scrape does not stall host churn. A plain
+// Counter.Inc/Gauge.Update loop would not do this, since those never touch
+// defaultStore.mutex at all.
+func BenchmarkCollectDoesNotBlockCallbacks(b *testing.B) {
+	for i := 0; i < 10000; i++ {
+		s, err := stats.NewStats("benchmark_metric", map[string]string{"idx": strconv.Itoa(i)})
+		if err != nil {
+			b.Fatal(err)
+		}
+		s.Counter("success").Inc(1)
+		s.Gauge("healthy").Update(1)
+	}
+
+	var churnRuns int64
+	var churnErr atomic.Value // holds error
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 10000; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+				if _, err := stats.NewStats("benchmark_metric", map[string]string{"idx": strconv.Itoa(i)}); err != nil {
+					churnErr.Store(err)
+					return
+				}
+				atomic.AddInt64(&churnRuns, 1)
+			}
+		}
+	}()
+	defer func() {
+		close(stop)
+		wg.Wait()
+		if err, ok := churnErr.Load().(error); ok {
+			b.Fatal(err)
+		}
+	}()
+
+	c := NewCollector()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ch := make(chan prometheus.Metric, 4096)
+		done := make(chan struct{})
+		go func() {
+			for range ch {
+			}
+			close(done)
+		}()
+		c.Collect(ch)
+		close(ch)
+		<-done
+	}
+}
@@ -32,8 +32,52 @@ var (
 	defaultStore *store
 
 	errLabelCountExceeded = fmt.Errorf("label count exceeded, max is % %d", maxLabelCount)
+
+	exporters      []Exporter
+	exportersMutex sync.Mutex
 )
 
+// MaxLabelCount returns the maximum number of labels a single Stats may carry.
+// Exporters should enforce the same limit when registering metrics downstream.
+func MaxLabelCount() int {
+	return maxLabelCount
+}
+
+// Exporter is notified of every Stats instance as it is created, so alternative
+// metrics sinks (statsd, OpenTelemetry, ...) can plug in through the same
+// iteration a pull-based exporter like Prometheus walks via GetAll.
+type Exporter interface {
+	Export(m types.Metrics)
+}
+
+// RegisterExporter registers a sink that receives every existing Stats immediately
+// and every Stats created afterwards. Intended to be called once, at init time.
+func RegisterExporter(e Exporter) {
+	defaultStore.mutex.RLock()
+	existing := make([]types.Metrics, len(defaultStore.metrics))
+	copy(existing, defaultStore.metrics)
+	defaultStore.mutex.RUnlock()
+
+	exportersMutex.Lock()
+	exporters = append(exporters, e)
+	exportersMutex.Unlock()
+
+	for _, m := range existing {
+		e.Export(m)
+	}
+}
+
+func notifyExporters(m types.Metrics) {
+	exportersMutex.Lock()
+	current := make([]Exporter, len(exporters))
+	copy(current, exporters)
+	exportersMutex.Unlock()
+
+	for _, e := range current {
+		e.Export(m)
+	}
+}
+
 // stats memory store
 type store struct {
 	metrics []types.Metrics
@@ -65,11 +109,11 @@ func NewStats(typ string, labels map[string]string) (types.Metrics, error) {
 	}
 
 	defaultStore.mutex.Lock()
-	defer defaultStore.mutex.Unlock()
 
 	// check existence
 	for _, metric := range defaultStore.metrics {
 		if metric.Type() == typ && mapEqual(metric.Labels(), labels) {
+			defaultStore.mutex.Unlock()
 			return metric, nil
 		}
 	}
@@ -81,6 +125,12 @@ func NewStats(typ string, labels map[string]string) (types.Metrics, error) {
 	}
 
 	defaultStore.metrics = append(defaultStore.metrics, stats)
+	defaultStore.mutex.Unlock()
+
+	// Exporters run outside the lock: Export may do network I/O (statsd,
+	// OpenTelemetry) or call back into GetAll/NewStats, and defaultStore.mutex
+	// is not reentrant.
+	notifyExporters(stats)
 
 	return stats, nil
 }
@@ -1,62 +1,56 @@
 package sofarpc
 
 import (
-	"sync/atomic"
+	"sync"
 	"time"
+
+	"github.com/alipay/sofa-mosn/pkg/scheduler"
 )
 
+// defaultScheduler backs every timer in this package, so N sofarpc timers cost
+// one ticker goroutine instead of N.
+var defaultScheduler = scheduler.New()
+
 // thread-safe reusable timer
+//
+// This used to manage its own goroutine, channel and atomic flags directly:
+// stop() sent on a buffered stopChan that the goroutine had already stopped
+// reading from once it fired, so the send was silently lost; start() reset
+// `stopped` only inside the fired goroutine's defer, so a concurrent stop()
+// immediately followed by start() could race and deadlock; and close() on an
+// already-drained stopChan could panic on double-close. It is now a thin shim
+// over pkg/scheduler, which owns cancellation instead of hand-rolled channels.
 type timer struct {
-	callback   func()
-	interval   time.Duration
-	innerTimer *time.Timer
-	stopped    int32
-	started    int32
-	stopChan   chan bool
+	callback func()
+
+	mutex  sync.Mutex
+	handle scheduler.Handle
 }
 
 func newTimer(callback func()) *timer {
-	return &timer{
-		callback: callback,
-		stopChan: make(chan bool, 1),
-	}
+	return &timer{callback: callback}
 }
 
 func (t *timer) start(interval time.Duration) {
-	if !atomic.CompareAndSwapInt32(&t.started, 0, 1) {
-		return
-	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
 
-	if t.innerTimer == nil {
-		t.innerTimer = time.NewTimer(interval)
-	} else {
-		t.innerTimer.Reset(interval)
+	if t.handle != nil {
+		t.handle.Cancel()
 	}
-
-	go func() {
-		defer func() {
-			t.innerTimer.Stop()
-			atomic.StoreInt32(&t.started, 0)
-			atomic.StoreInt32(&t.stopped, 0)
-		}()
-
-		select {
-		case <-t.innerTimer.C:
-			t.callback()
-		case <-t.stopChan:
-			return
-		}
-	}()
+	t.handle = defaultScheduler.Schedule(interval, 0, t.callback)
 }
 
 func (t *timer) stop() {
-	if !atomic.CompareAndSwapInt32(&t.stopped, 0, 1) {
-		return
-	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
 
-	t.stopChan <- true
+	if t.handle != nil {
+		t.handle.Cancel()
+		t.handle = nil
+	}
 }
 
 func (t *timer) close() {
-	close(t.stopChan)
+	t.stop()
 }
@@ -0,0 +1,97 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package healthcheck
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeSession is a minimal types.HealthCheckSession: this package only ever
+// calls CheckHealth() on a session, so that's the only method faked here.
+type fakeSession struct {
+	healthy bool
+	err     error
+}
+
+func (s *fakeSession) CheckHealth() (bool, error) { return s.healthy, s.err }
+
+// fakeCtxSession additionally implements the optional contextSetter interface.
+type fakeCtxSession struct {
+	fakeSession
+	ctx context.Context
+}
+
+func (s *fakeCtxSession) SetContext(ctx context.Context) { s.ctx = ctx }
+
+var _ contextSetter = (*fakeCtxSession)(nil)
+
+func TestStaleCheckErrorMessage(t *testing.T) {
+	if got := errStaleCheck.Error(); got == "" {
+		t.Fatal("errStaleCheck.Error() should not be empty")
+	}
+}
+
+func TestCheckerStatusReflectsLastResult(t *testing.T) {
+	hc := &healthChecker{intervalBase: time.Hour, timeout: time.Hour}
+	host := &fakeHost{addr: "10.0.0.3:8080"}
+	c := newChecker(&fakeSession{}, host, hc)
+
+	c.mutex.Lock()
+	c.healthy = false
+	c.lastError = errStaleCheck
+	c.mutex.Unlock()
+
+	status := c.Status()
+	if status.Healthy {
+		t.Fatal("Status().Healthy = true, want false")
+	}
+	if status.LastError != errStaleCheck {
+		t.Fatalf("Status().LastError = %v, want errStaleCheck", status.LastError)
+	}
+}
+
+// TestCheckerStartStopCancelsHandles exercises the scheduler-backed Start/Stop
+// lifecycle. The interval is long enough that neither the check nor the
+// watchdog callback ever fires during the test, since onCheckResult/
+// checkStaleness both report through healthChecker.stats, and
+// healthCheckStats/newHealthCheckStats are referenced by healthchecker.go but
+// are not part of this checkout.
+func TestCheckerStartStopCancelsHandles(t *testing.T) {
+	hc := &healthChecker{intervalBase: time.Hour, timeout: time.Hour}
+	host := &fakeHost{addr: "10.0.0.4:8080"}
+	c := newChecker(&fakeSession{healthy: true}, host, hc)
+
+	c.Start()
+	if c.checkHandle == nil || c.watchdogHandle == nil {
+		t.Fatal("Start should arm both the check and watchdog handles")
+	}
+
+	c.Stop()
+	c.handleMutex.Lock()
+	stopped := c.stopped
+	c.handleMutex.Unlock()
+	if !stopped {
+		t.Fatal("Stop should mark the checker stopped")
+	}
+
+	// Stop must be safe to call again (e.g. a duplicate cluster-member-removal
+	// event) without panicking on an already-canceled handle.
+	c.Stop()
+}
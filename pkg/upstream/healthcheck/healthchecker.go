@@ -41,6 +41,7 @@ type healthChecker struct {
 	sessionFactory      types.HealthCheckSessionFactory
 	mutex               sync.Mutex
 	checkers            map[string]*checker
+	hosts               map[string]types.Host
 	localProcessHealthy int64
 	stats               *healthCheckStats
 	// check config
@@ -51,6 +52,10 @@ type healthChecker struct {
 	unhealthyThreshold uint32
 	rander             *rand.Rand
 	hostCheckCallbacks []types.HealthCheckCb
+	// passive is the outlier-detection monitor sharing this checker's callbacks and
+	// stats; nil when the cluster has no passive health check configured.
+	passive     *PassiveHealthMonitor
+	stopPassive chan struct{}
 }
 
 func newHealthChecker(cfg v2.HealthCheck, cluster types.Cluster, f types.HealthCheckSessionFactory) types.HealthChecker {
@@ -77,6 +82,7 @@ func newHealthChecker(cfg v2.HealthCheck, cluster types.Cluster, f types.HealthC
 		sessionFactory:     f,
 		mutex:              sync.Mutex{},
 		checkers:           make(map[string]*checker),
+		hosts:              make(map[string]types.Host),
 		stats:              newHealthCheckStats(cfg.ServiceName),
 	}
 	// Add common callbacks when create
@@ -86,6 +92,12 @@ func newHealthChecker(cfg v2.HealthCheck, cluster types.Cluster, f types.HealthC
 			hc.AddHostCheckCompleteCb(cb)
 		}
 	}
+	// Passive (outlier-detection) health checking is configured through the
+	// existing, generic SessionConfig bucket rather than new v2.HealthCheck
+	// fields, since that struct is outside this change.
+	if passiveCfg, ok := cfg.SessionConfig["passiveHealthCheck"].(map[string]interface{}); ok {
+		hc.passive = NewPassiveHealthMonitor(passiveCfg, hc)
+	}
 	return hc
 }
 
@@ -97,6 +109,9 @@ func (hc *healthChecker) Start() {
 		}
 	}
 	hc.stats.healthy.Update(hc.localProcessHealthy)
+	if hc.passive != nil {
+		go hc.runPassiveSweep()
+	}
 }
 
 func (hc *healthChecker) Stop() {
@@ -106,6 +121,63 @@ func (hc *healthChecker) Stop() {
 			hc.stopCheck(h)
 		}
 	}
+	if hc.passive != nil && hc.stopPassive != nil {
+		close(hc.stopPassive)
+	}
+}
+
+// runPassiveSweep periodically restores hosts whose passive ejection window elapsed.
+// It reuses the active checker's own interval so there is no extra configuration knob.
+func (hc *healthChecker) runPassiveSweep() {
+	hc.stopPassive = make(chan struct{})
+	ticker := time.NewTicker(hc.intervalBase)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			hc.passive.sweep()
+		case <-hc.stopPassive:
+			return
+		}
+	}
+}
+
+// EjectedHosts returns the addresses currently ejected by passive health checking.
+// It is exposed so the admin server can list outlier-detection state per cluster.
+func (hc *healthChecker) EjectedHosts() []string {
+	if hc.passive == nil {
+		return nil
+	}
+	return hc.passive.EjectedHosts()
+}
+
+// Unject forces an ejected host back into rotation, bypassing the remaining
+// ejection window. Used by the admin API's force-unject endpoint.
+func (hc *healthChecker) Unject(addr string) bool {
+	if hc.passive == nil {
+		return false
+	}
+	return hc.passive.Unject(addr)
+}
+
+// CheckStatus returns the last known {healthy, lastCheckedAt, lastError} for the
+// host at addr, so the admin endpoint can report per-host state without waiting
+// for the next scheduled check.
+func (hc *healthChecker) CheckStatus(addr string) (CheckStatus, bool) {
+	hc.mutex.Lock()
+	c, ok := hc.checkers[addr]
+	hc.mutex.Unlock()
+	if !ok {
+		return CheckStatus{}, false
+	}
+	return c.Status(), true
+}
+
+func (hc *healthChecker) hostByAddr(addr string) (types.Host, bool) {
+	hc.mutex.Lock()
+	defer hc.mutex.Unlock()
+	h, ok := hc.hosts[addr]
+	return h, ok
 }
 
 func (hc *healthChecker) AddHostCheckCompleteCb(cb types.HealthCheckCb) {
@@ -134,7 +206,8 @@ func (hc *healthChecker) startCheck(host types.Host) {
 		}
 		c := newChecker(s, host, hc)
 		hc.checkers[addr] = c
-		go c.Start()
+		hc.hosts[addr] = host
+		c.Start()
 		hc.localProcessHealthy++ // default host is healthy
 		log.DefaultLogger.Infof("create a health check session for %s", addr)
 	}
@@ -147,6 +220,7 @@ func (hc *healthChecker) stopCheck(host types.Host) {
 	if c, ok := hc.checkers[addr]; ok {
 		c.Stop()
 		delete(hc.checkers, addr)
+		delete(hc.hosts, addr)
 		hc.localProcessHealthy-- // deleted check is unhealthy
 		log.DefaultLogger.Infof("remove a health check session for %s", addr)
 	}
@@ -186,7 +260,7 @@ func (hc *healthChecker) decHealthy(host types.Host, reason types.FailureType, c
 		hc.stats.activeFailure.Inc(1)
 	case types.FailureNetwork:
 		hc.stats.networkFailure.Inc(1)
-	case types.FailurePassive: //TODO: not support yet
+	case types.FailurePassive:
 		hc.stats.passiveFailure.Inc(1)
 	}
 	hc.runCallbacks(host, changed, false)
@@ -0,0 +1,237 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package healthcheck
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/alipay/sofa-mosn/pkg/types"
+)
+
+func init() {
+	f := &HTTPHealthCheckSessionFactory{}
+	RegisterSessionFactory(types.Protocol("HTTP"), f)
+	RegisterSessionFactory(types.Protocol("HTTPS"), f)
+}
+
+// HTTPHealthCheckSessionFactory creates HTTP(S) health check sessions that judge
+// a host's health by status code and, optionally, response body, rather than just
+// TCP reachability.
+type HTTPHealthCheckSessionFactory struct{}
+
+// NewSession builds a httpHealthCheckSession for host, configured from cfg.
+func (f *HTTPHealthCheckSessionFactory) NewSession(cfg map[string]interface{}, host types.Host) types.HealthCheckSession {
+	s := &httpHealthCheckSession{
+		host:    host,
+		client:  &http.Client{},
+		scheme:  "http",
+		path:    "/",
+		method:  http.MethodGet,
+		ranges:  []statusRange{{200, 299}},
+		headers: map[string]string{},
+	}
+
+	if v, ok := cfg["path"].(string); ok && v != "" {
+		s.path = v
+	}
+	if v, ok := cfg["host"].(string); ok && v != "" {
+		s.vHost = v
+	}
+	if v, ok := cfg["method"].(string); ok && v != "" {
+		s.method = strings.ToUpper(v)
+	}
+	if v, ok := cfg["expectedStatuses"].(string); ok && v != "" {
+		if ranges, err := parseStatusRanges(v); err == nil {
+			s.ranges = ranges
+		}
+	}
+	if v, ok := cfg["expectedBodyRegex"].(string); ok && v != "" {
+		if re, err := regexp.Compile(v); err == nil {
+			s.bodyRegex = re
+		}
+	}
+	if raw, ok := cfg["requestHeaders"].(map[string]interface{}); ok {
+		for k, v := range raw {
+			if sv, ok := v.(string); ok {
+				s.headers[k] = sv
+			}
+		}
+	}
+	if tlsCfg, ok := cfg["tls"].(map[string]interface{}); ok {
+		s.client.Transport = buildTLSTransport(tlsCfg)
+		s.scheme = "https"
+	}
+
+	return s
+}
+
+type statusRange struct {
+	min, max int
+}
+
+func (r statusRange) contains(status int) bool {
+	return status >= r.min && status <= r.max
+}
+
+// parseStatusRanges parses strings like "200-299,301,404" into statusRange values.
+func parseStatusRanges(s string) ([]statusRange, error) {
+	var ranges []statusRange
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if idx := strings.IndexByte(part, '-'); idx >= 0 {
+			min, err := strconv.Atoi(part[:idx])
+			if err != nil {
+				return nil, err
+			}
+			max, err := strconv.Atoi(part[idx+1:])
+			if err != nil {
+				return nil, err
+			}
+			ranges = append(ranges, statusRange{min, max})
+			continue
+		}
+		v, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, statusRange{v, v})
+	}
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("no status ranges parsed from %q", s)
+	}
+	return ranges, nil
+}
+
+func buildTLSTransport(cfg map[string]interface{}) *http.Transport {
+	tlsConfig := &tls.Config{}
+	if sni, ok := cfg["sni"].(string); ok && sni != "" {
+		tlsConfig.ServerName = sni
+	}
+	if certFile, ok := cfg["certFile"].(string); ok && certFile != "" {
+		if keyFile, ok := cfg["keyFile"].(string); ok && keyFile != "" {
+			if cert, err := tls.LoadX509KeyPair(certFile, keyFile); err == nil {
+				tlsConfig.Certificates = []tls.Certificate{cert}
+			}
+		}
+	}
+	return &http.Transport{TLSClientConfig: tlsConfig}
+}
+
+// httpHealthCheckSession is a types.HealthCheckSession that issues a single HTTP
+// request per check and classifies dial/IO errors as types.FailureNetwork, and
+// unexpected status/body as types.FailureActive.
+type httpHealthCheckSession struct {
+	host types.Host
+
+	client *http.Client
+	// scheme is "http" unless a "tls" block was configured, in which case it is
+	// "https" and client's Transport carries the corresponding TLSClientConfig.
+	scheme    string
+	path      string
+	vHost     string
+	method    string
+	ranges    []statusRange
+	bodyRegex *regexp.Regexp
+	headers   map[string]string
+
+	// ctx is set by checker.runCheck via SetContext before each CheckHealth call,
+	// so Stop() on the owning checker cancels an in-flight request instead of
+	// leaking it. Only runCheck touches it, and the next check is scheduled only
+	// after the previous one has returned, so accesses never overlap.
+	ctx context.Context
+}
+
+// SetContext implements the optional contextSetter interface (see checker.go).
+// checker.runCheck calls this immediately before CheckHealth.
+func (s *httpHealthCheckSession) SetContext(ctx context.Context) {
+	s.ctx = ctx
+}
+
+// CheckHealth issues one HTTP request against the host, bounded by the context
+// set through SetContext.
+func (s *httpHealthCheckSession) CheckHealth() (bool, error) {
+	ctx := s.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	url := fmt.Sprintf("%s://%s%s", s.scheme, s.host.AddressString(), s.path)
+	req, err := http.NewRequest(s.method, url, nil)
+	if err != nil {
+		return false, err
+	}
+	req = req.WithContext(ctx)
+	if s.vHost != "" {
+		req.Host = s.vHost
+	}
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		// dial/IO error: treat distinctly from a bad status/body
+		return false, &networkFailureError{err}
+	}
+	defer resp.Body.Close()
+
+	if !s.statusExpected(resp.StatusCode) {
+		return false, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	if s.bodyRegex != nil {
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return false, &networkFailureError{err}
+		}
+		if !s.bodyRegex.Match(body) {
+			return false, fmt.Errorf("response body did not match %s", s.bodyRegex.String())
+		}
+	}
+
+	return true, nil
+}
+
+func (s *httpHealthCheckSession) statusExpected(status int) bool {
+	for _, r := range s.ranges {
+		if r.contains(status) {
+			return true
+		}
+	}
+	return false
+}
+
+// networkFailureError marks an error as a dial/IO failure rather than a bad
+// response, so checker.onCheckResult (called by runCheck) can report
+// types.FailureNetwork instead of types.FailureActive.
+type networkFailureError struct {
+	err error
+}
+
+func (e *networkFailureError) Error() string { return e.err.Error() }
+func (e *networkFailureError) Unwrap() error { return e.err }
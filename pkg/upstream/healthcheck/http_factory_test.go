@@ -0,0 +1,77 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package healthcheck
+
+import "testing"
+
+func TestParseStatusRanges(t *testing.T) {
+	ranges, err := parseStatusRanges("200-299,301,404")
+	if err != nil {
+		t.Fatalf("parseStatusRanges returned error: %v", err)
+	}
+	if len(ranges) != 3 {
+		t.Fatalf("len(ranges) = %d, want 3", len(ranges))
+	}
+
+	s := &httpHealthCheckSession{ranges: ranges}
+	for _, tc := range []struct {
+		status int
+		want   bool
+	}{
+		{200, true},
+		{250, true},
+		{299, true},
+		{300, false},
+		{301, true},
+		{404, true},
+		{500, false},
+	} {
+		if got := s.statusExpected(tc.status); got != tc.want {
+			t.Errorf("statusExpected(%d) = %v, want %v", tc.status, got, tc.want)
+		}
+	}
+}
+
+func TestParseStatusRangesInvalid(t *testing.T) {
+	if _, err := parseStatusRanges(""); err == nil {
+		t.Fatal("parseStatusRanges(\"\") should return an error")
+	}
+}
+
+// TestNewSessionSchemeFollowsTLSConfig is a regression test: a session
+// registered for HTTPS with a tls config block must actually issue requests
+// with the https scheme, not silently fall back to plain HTTP.
+func TestNewSessionSchemeFollowsTLSConfig(t *testing.T) {
+	f := &HTTPHealthCheckSessionFactory{}
+	host := &fakeHost{addr: "10.0.0.5:8443"}
+
+	plain := f.NewSession(map[string]interface{}{}, host).(*httpHealthCheckSession)
+	if plain.scheme != "http" {
+		t.Errorf("scheme with no tls config = %q, want %q", plain.scheme, "http")
+	}
+
+	secure := f.NewSession(map[string]interface{}{
+		"tls": map[string]interface{}{"sni": "upstream.example.com"},
+	}, host).(*httpHealthCheckSession)
+	if secure.scheme != "https" {
+		t.Errorf("scheme with tls config = %q, want %q", secure.scheme, "https")
+	}
+	if secure.client.Transport == nil {
+		t.Error("a tls config should install a Transport carrying the TLSClientConfig")
+	}
+}
@@ -0,0 +1,407 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package healthcheck
+
+import (
+	"sync"
+	"time"
+
+	"github.com/alipay/sofa-mosn/pkg/log"
+	"github.com/alipay/sofa-mosn/pkg/types"
+)
+
+const (
+	DefaultConsecutive5xx          = 5
+	DefaultConsecutiveGatewayError = 5
+	DefaultConsecutiveConnectError = 3
+	DefaultBaseEjectionTime        = 30 * time.Second
+	DefaultMaxEjectionPercent      = 10
+	DefaultSuccessRateWindow       = 20
+	DefaultSuccessRateThreshold    = 0.7
+)
+
+// outcome is the classification a passive observer reports for a single upstream request.
+type outcome int
+
+const (
+	outcomeSuccess outcome = iota
+	outcome5xx
+	outcomeGatewayError
+	outcomeConnectError
+	outcomeTimeout
+)
+
+// hostPassiveState tracks consecutive failure counters, a success-rate window,
+// and the current ejection window for a single host.
+type hostPassiveState struct {
+	mutex sync.Mutex
+
+	consecutive5xx     uint32
+	consecutiveGateway uint32
+	consecutiveConnect uint32
+
+	// window is a ring buffer of the last len(window) outcomes (true == success),
+	// used for the success-rate ejection criterion alongside the consecutive ones.
+	window    []bool
+	windowPos int
+	windowLen int
+
+	ejected       bool
+	ejectionCount uint32
+	ejectedAt     time.Time
+	ejectUntil    time.Time
+}
+
+func newHostPassiveState(windowSize int) *hostPassiveState {
+	return &hostPassiveState{
+		window: make([]bool, windowSize),
+	}
+}
+
+// record pushes an outcome into the success-rate window and returns the current
+// success rate once the window has filled at least once; ok is false until then,
+// since a rate computed from a handful of samples is not meaningful.
+func (s *hostPassiveState) record(success bool) (rate float64, ok bool) {
+	s.window[s.windowPos] = success
+	s.windowPos = (s.windowPos + 1) % len(s.window)
+	if s.windowLen < len(s.window) {
+		s.windowLen++
+	}
+
+	if s.windowLen < len(s.window) {
+		return 0, false
+	}
+
+	successes := 0
+	for _, v := range s.window {
+		if v {
+			successes++
+		}
+	}
+	return float64(successes) / float64(len(s.window)), true
+}
+
+// PassiveHealthMonitor observes per-request outcomes on the connection/stream
+// layer and ejects hosts that look unhealthy, independent of the active health
+// checker. It shares the healthChecker's hostCheckCallbacks pipeline and stats,
+// so an ejection is indistinguishable to callbacks from an active-check failure.
+//
+// Until the connection/stream filter layer calls the Notify* methods for live
+// traffic, this package's own HTTP active-check session (httpHealthCheckSession,
+// via checker.onCheckResult) feeds it the same events, so the monitor always has
+// at least one real producer instead of sitting dead.
+type PassiveHealthMonitor struct {
+	hc *healthChecker
+
+	consecutive5xxThreshold     uint32
+	consecutiveGatewayThreshold uint32
+	consecutiveConnectThreshold uint32
+	baseEjectionTime            time.Duration
+	maxEjectionPercent          uint32
+	successRateWindow           int
+	successRateThreshold        float64
+
+	mutex  sync.Mutex
+	states map[string]*hostPassiveState
+}
+
+// NewPassiveHealthMonitor creates a PassiveHealthMonitor for a cluster's health
+// checker. cfg is the "passiveHealthCheck" sub-map of v2.HealthCheck.SessionConfig
+// (the same generic, already-existing config bucket the session factories read),
+// since v2.HealthCheck itself has no typed outlier-detection fields yet. Missing
+// or malformed keys fall back to sane defaults.
+func NewPassiveHealthMonitor(cfg map[string]interface{}, hc *healthChecker) *PassiveHealthMonitor {
+	p := &PassiveHealthMonitor{
+		hc:                          hc,
+		consecutive5xxThreshold:     DefaultConsecutive5xx,
+		consecutiveGatewayThreshold: DefaultConsecutiveGatewayError,
+		consecutiveConnectThreshold: DefaultConsecutiveConnectError,
+		baseEjectionTime:            DefaultBaseEjectionTime,
+		maxEjectionPercent:          DefaultMaxEjectionPercent,
+		successRateWindow:           DefaultSuccessRateWindow,
+		successRateThreshold:        DefaultSuccessRateThreshold,
+		states:                      make(map[string]*hostPassiveState),
+	}
+
+	if v, ok := cfg["consecutiveGatewayFailure"].(float64); ok && v != 0 {
+		p.consecutiveGatewayThreshold = uint32(v)
+	}
+	if v, ok := cfg["consecutive5xx"].(float64); ok && v != 0 {
+		p.consecutive5xxThreshold = uint32(v)
+	}
+	if v, ok := cfg["consecutiveConnectFailure"].(float64); ok && v != 0 {
+		p.consecutiveConnectThreshold = uint32(v)
+	}
+	if v, ok := cfg["baseEjectionTimeMs"].(float64); ok && v != 0 {
+		p.baseEjectionTime = time.Duration(v) * time.Millisecond
+	}
+	if v, ok := cfg["maxEjectionPercent"].(float64); ok && v != 0 {
+		p.maxEjectionPercent = uint32(v)
+	}
+	if v, ok := cfg["successRateWindowSize"].(float64); ok && v != 0 {
+		p.successRateWindow = int(v)
+	}
+	if v, ok := cfg["successRateThreshold"].(float64); ok && v != 0 {
+		p.successRateThreshold = v
+	}
+
+	return p
+}
+
+func (p *PassiveHealthMonitor) stateFor(host types.Host) *hostPassiveState {
+	addr := host.AddressString()
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	s, ok := p.states[addr]
+	if !ok {
+		s = newHostPassiveState(p.successRateWindow)
+		p.states[addr] = s
+	}
+	return s
+}
+
+// NotifyHTTPStatus reports the status code of a completed upstream request. It
+// returns whether this observation caused host to be ejected.
+func (p *PassiveHealthMonitor) NotifyHTTPStatus(host types.Host, status int) bool {
+	switch {
+	case status == 502 || status == 503 || status == 504:
+		return p.observe(host, outcomeGatewayError)
+	case status >= 500:
+		return p.observe(host, outcome5xx)
+	default:
+		return p.observe(host, outcomeSuccess)
+	}
+}
+
+// NotifyConnectFailure reports that a connection attempt to host failed. It
+// returns whether this observation caused host to be ejected.
+func (p *PassiveHealthMonitor) NotifyConnectFailure(host types.Host) bool {
+	return p.observe(host, outcomeConnectError)
+}
+
+// NotifyTimeout reports that a request to host timed out waiting for a
+// response. It returns whether this observation caused host to be ejected.
+func (p *PassiveHealthMonitor) NotifyTimeout(host types.Host) bool {
+	return p.observe(host, outcomeTimeout)
+}
+
+// NotifySuccess reports that a request to host completed without error. It
+// returns whether this observation caused host to be ejected (always false).
+func (p *PassiveHealthMonitor) NotifySuccess(host types.Host) bool {
+	return p.observe(host, outcomeSuccess)
+}
+
+// NotifyActiveFailure reports a non-network active-check failure (bad status or
+// body) for callers, like the active checker itself, that don't have an actual
+// HTTP status code to classify. It returns whether this observation caused host
+// to be ejected.
+func (p *PassiveHealthMonitor) NotifyActiveFailure(host types.Host) bool {
+	return p.observe(host, outcome5xx)
+}
+
+// observe updates host's counters and window under its own state lock only, and
+// decides whether the host should be ejected. The actual ejection always runs
+// with the state lock released, so eject is free to call ejectedPercent (which
+// takes every host's state lock in turn) without deadlocking against itself. It
+// returns whether this observation caused host to be ejected, so callers that
+// also report failures through their own path (e.g. checker.onCheckResult via
+// decHealthy) can avoid double-reporting the same failure.
+func (p *PassiveHealthMonitor) observe(host types.Host, o outcome) bool {
+	s := p.stateFor(host)
+
+	s.mutex.Lock()
+	if s.ejected {
+		s.mutex.Unlock()
+		return false
+	}
+
+	success := o == outcomeSuccess
+	shouldEject := false
+
+	switch o {
+	case outcomeSuccess:
+		s.consecutive5xx = 0
+		s.consecutiveGateway = 0
+		s.consecutiveConnect = 0
+	case outcome5xx:
+		s.consecutive5xx++
+		shouldEject = s.consecutive5xx >= p.consecutive5xxThreshold
+	case outcomeGatewayError, outcomeTimeout:
+		s.consecutiveGateway++
+		shouldEject = s.consecutiveGateway >= p.consecutiveGatewayThreshold
+	case outcomeConnectError:
+		s.consecutiveConnect++
+		shouldEject = s.consecutiveConnect >= p.consecutiveConnectThreshold
+	}
+
+	if rate, ok := s.record(success); ok && !success && rate < p.successRateThreshold {
+		shouldEject = true
+	}
+	s.mutex.Unlock()
+
+	if !shouldEject {
+		return false
+	}
+	return p.eject(host, s)
+}
+
+// eject marks the host unhealthy for base*ejectionCount, capped, and reports the
+// ejection through the shared decHealthy/runCallbacks pipeline so stats and
+// callbacks stay consistent with active-check failures. Called with s unlocked.
+// It returns whether an ejection actually happened, since the max-ejection-percent
+// cap or a concurrent ejection of the same host can both turn this into a no-op.
+func (p *PassiveHealthMonitor) eject(host types.Host, s *hostPassiveState) bool {
+	if p.ejectedPercent() >= p.maxEjectionPercent {
+		log.DefaultLogger.Warnf("passive health check: max ejection percent %d%% reached, not ejecting %s",
+			p.maxEjectionPercent, host.AddressString())
+		return false
+	}
+
+	s.mutex.Lock()
+	if s.ejected {
+		s.mutex.Unlock()
+		return false
+	}
+	s.ejected = true
+	s.ejectionCount++
+	s.ejectedAt = time.Now()
+	duration := p.baseEjectionTime * time.Duration(s.ejectionCount)
+	if maxDuration := p.baseEjectionTime * 10; duration > maxDuration {
+		duration = maxDuration
+	}
+	s.ejectUntil = s.ejectedAt.Add(duration)
+	s.mutex.Unlock()
+
+	log.DefaultLogger.Warnf("passive health check: ejecting %s for %s", host.AddressString(), duration)
+	p.hc.decHealthy(host, types.FailurePassive, true)
+	return true
+}
+
+// ejectedPercent locks each host's state in turn; callers must never hold any
+// single hostPassiveState's mutex when calling this, or they will deadlock
+// against themselves here.
+func (p *PassiveHealthMonitor) ejectedPercent() uint32 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if len(p.states) == 0 {
+		return 0
+	}
+	var ejected uint32
+	for _, s := range p.states {
+		s.mutex.Lock()
+		if s.ejected {
+			ejected++
+		}
+		s.mutex.Unlock()
+	}
+	return ejected * 100 / uint32(len(p.states))
+}
+
+// sweep restores hosts whose ejection window has elapsed. It is driven by the
+// healthChecker's own check interval, so restoration is gradual rather than
+// instantaneous across the whole cluster.
+func (p *PassiveHealthMonitor) sweep() {
+	p.mutex.Lock()
+	addrs := make([]string, 0, len(p.states))
+	for addr := range p.states {
+		addrs = append(addrs, addr)
+	}
+	p.mutex.Unlock()
+
+	now := time.Now()
+	for _, addr := range addrs {
+		p.mutex.Lock()
+		s := p.states[addr]
+		p.mutex.Unlock()
+
+		s.mutex.Lock()
+		restore := s.ejected && now.After(s.ejectUntil)
+		if restore {
+			s.ejected = false
+			s.consecutive5xx = 0
+			s.consecutiveGateway = 0
+			s.consecutiveConnect = 0
+		}
+		s.mutex.Unlock()
+
+		if restore {
+			if host, ok := p.hc.hostByAddr(addr); ok {
+				log.DefaultLogger.Infof("passive health check: restoring %s", addr)
+				p.hc.incHealthy(host, true)
+			}
+		}
+	}
+}
+
+// EjectedHosts returns the addresses of hosts currently ejected by outlier detection.
+func (p *PassiveHealthMonitor) EjectedHosts() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	ejected := make([]string, 0)
+	for addr, s := range p.states {
+		s.mutex.Lock()
+		if s.ejected {
+			ejected = append(ejected, addr)
+		}
+		s.mutex.Unlock()
+	}
+	return ejected
+}
+
+// Unject forcibly restores an ejected host, e.g. via an operator admin request.
+func (p *PassiveHealthMonitor) Unject(addr string) bool {
+	p.mutex.Lock()
+	s, ok := p.states[addr]
+	p.mutex.Unlock()
+	if !ok {
+		return false
+	}
+
+	s.mutex.Lock()
+	wasEjected := s.ejected
+	s.ejected = false
+	s.consecutive5xx = 0
+	s.consecutiveGateway = 0
+	s.consecutiveConnect = 0
+	s.mutex.Unlock()
+
+	if wasEjected {
+		if host, ok := p.hc.hostByAddr(addr); ok {
+			p.hc.incHealthy(host, true)
+		}
+	}
+	return wasEjected
+}
+
+// EjectionLister is implemented by HealthCheckers that support passive
+// outlier-detection admin operations. types.HealthChecker has no such members
+// (this package cannot add any, since pkg/types is outside this change), so
+// admin code holding a types.HealthChecker should type-assert to this narrower
+// interface instead of requiring every HealthChecker to implement it:
+//
+//	if el, ok := checker.(healthcheck.EjectionLister); ok {
+//	    el.EjectedHosts()
+//	}
+type EjectionLister interface {
+	EjectedHosts() []string
+	Unject(addr string) bool
+}
+
+var _ EjectionLister = (*healthChecker)(nil)
@@ -0,0 +1,137 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package healthcheck
+
+import "testing"
+
+// fakeHost is a minimal stand-in for types.Host, implementing only the method
+// this package ever calls on it (AddressString). pkg/types is not part of this
+// checkout, so a fake implementing its full method set isn't possible here.
+type fakeHost struct {
+	addr string
+}
+
+func (h *fakeHost) AddressString() string { return h.addr }
+
+func TestHostPassiveStateRecordSuccessRate(t *testing.T) {
+	s := newHostPassiveState(4)
+
+	for i, success := range []bool{true, true, true} {
+		if _, ok := s.record(success); ok {
+			t.Fatalf("record #%d: window not yet full, ok should be false", i)
+		}
+	}
+
+	rate, ok := s.record(false)
+	if !ok {
+		t.Fatal("record: window just filled, ok should be true")
+	}
+	if want := 0.75; rate != want {
+		t.Fatalf("rate = %v, want %v", rate, want)
+	}
+
+	// the window is a ring buffer: the next record evicts the oldest sample.
+	rate, ok = s.record(false)
+	if !ok {
+		t.Fatal("record: window stays full, ok should remain true")
+	}
+	if want := 0.5; rate != want {
+		t.Fatalf("rate after second failure = %v, want %v", rate, want)
+	}
+}
+
+func TestNewPassiveHealthMonitorConfigDefaults(t *testing.T) {
+	p := NewPassiveHealthMonitor(nil, nil)
+
+	if p.consecutive5xxThreshold != DefaultConsecutive5xx {
+		t.Errorf("consecutive5xxThreshold = %d, want default %d", p.consecutive5xxThreshold, DefaultConsecutive5xx)
+	}
+	if p.successRateWindow != DefaultSuccessRateWindow {
+		t.Errorf("successRateWindow = %d, want default %d", p.successRateWindow, DefaultSuccessRateWindow)
+	}
+	if p.successRateThreshold != DefaultSuccessRateThreshold {
+		t.Errorf("successRateThreshold = %v, want default %v", p.successRateThreshold, DefaultSuccessRateThreshold)
+	}
+}
+
+func TestNewPassiveHealthMonitorConfigOverrides(t *testing.T) {
+	cfg := map[string]interface{}{
+		"consecutiveConnectFailure": float64(2),
+		"successRateWindowSize":     float64(5),
+		"successRateThreshold":      0.5,
+	}
+	p := NewPassiveHealthMonitor(cfg, nil)
+
+	if p.consecutiveConnectThreshold != 2 {
+		t.Errorf("consecutiveConnectThreshold = %d, want 2", p.consecutiveConnectThreshold)
+	}
+	if p.successRateWindow != 5 {
+		t.Errorf("successRateWindow = %d, want 5", p.successRateWindow)
+	}
+	if p.successRateThreshold != 0.5 {
+		t.Errorf("successRateThreshold = %v, want 0.5", p.successRateThreshold)
+	}
+}
+
+// TestObserveResetsConsecutiveCountersOnSuccess exercises observe()'s counter
+// bookkeeping directly, staying below every threshold so eject (and therefore
+// p.hc.decHealthy) is never reached: healthCheckStats/newHealthCheckStats,
+// which decHealthy needs, are referenced by healthchecker.go but are not part
+// of this checkout.
+func TestObserveResetsConsecutiveCountersOnSuccess(t *testing.T) {
+	p := NewPassiveHealthMonitor(map[string]interface{}{
+		"consecutiveConnectFailure": float64(100),
+		"successRateWindowSize":     float64(100),
+	}, nil)
+	host := &fakeHost{addr: "10.0.0.1:8080"}
+
+	if ejected := p.observe(host, outcomeConnectError); ejected {
+		t.Fatal("observe should not eject while under threshold")
+	}
+	s := p.stateFor(host)
+	if s.consecutiveConnect != 1 {
+		t.Fatalf("consecutiveConnect = %d, want 1", s.consecutiveConnect)
+	}
+
+	if ejected := p.observe(host, outcomeSuccess); ejected {
+		t.Fatal("a success observation should never eject")
+	}
+	if s.consecutiveConnect != 0 {
+		t.Fatalf("consecutiveConnect after success = %d, want 0 (reset)", s.consecutiveConnect)
+	}
+}
+
+// TestObserveSkipsAlreadyEjectedHost checks that observe short-circuits on an
+// already-ejected host's state: it reports no new ejection and leaves the host's
+// consecutive-failure counters untouched.
+func TestObserveSkipsAlreadyEjectedHost(t *testing.T) {
+	p := NewPassiveHealthMonitor(nil, nil)
+	host := &fakeHost{addr: "10.0.0.2:8080"}
+
+	s := p.stateFor(host)
+	s.mutex.Lock()
+	s.ejected = true
+	s.mutex.Unlock()
+
+	if ejected := p.observe(host, outcomeConnectError); ejected {
+		t.Fatal("observe should report no new ejection for a host that is already ejected")
+	}
+	if s.consecutiveConnect != 0 {
+		t.Fatalf("consecutiveConnect = %d, want 0 (observe should skip an ejected host)", s.consecutiveConnect)
+	}
+}